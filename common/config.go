@@ -0,0 +1,15 @@
+package common
+
+// DockerConfig is the Docker executor's configuration, as set in a runner's
+// config.toml [runners.docker] section.
+type DockerConfig struct {
+	Image string `toml:"image" json:"image" long:"image" env:"DOCKER_IMAGE" description:"Docker image to be used"`
+
+	// LazyPull opts into the stargz fast-path. It's a no-op unless
+	// Snapshotter is also set to "stargz" and the daemon confirms it's
+	// actually running with that snapshotter.
+	LazyPull bool `toml:"lazy_pull,omitempty" json:"lazy_pull,omitempty" long:"lazy-pull" env:"DOCKER_LAZY_PULL" description:"Skip prefetching images and let the containerd snapshotter lazily pull eStargz layers on demand"`
+	// Snapshotter is the containerd snapshotter the daemon is expected to
+	// be configured with (overlayfs or stargz).
+	Snapshotter string `toml:"snapshotter,omitempty" json:"snapshotter,omitempty" long:"snapshotter" env:"DOCKER_SNAPSHOTTER" description:"Containerd snapshotter the Docker daemon is configured with (overlayfs or stargz)"`
+}
@@ -0,0 +1,37 @@
+package pull
+
+// Snapshotter identifies the containerd snapshotter a Docker daemon has been
+// configured with.
+type Snapshotter string
+
+const (
+	// SnapshotterOverlayFS is the default graph-driver backed snapshotter.
+	// No lazy pulling is possible with it.
+	SnapshotterOverlayFS Snapshotter = "overlayfs"
+	// SnapshotterStargz is containerd's stargz-snapshotter, able to mount an
+	// eStargz image as a FUSE filesystem and fetch chunks on demand.
+	SnapshotterStargz Snapshotter = "stargz"
+)
+
+// Config controls whether the Manager is allowed to skip the runner's own
+// prefetching `docker pull` and rely on the daemon mounting an eStargz image
+// lazily instead.
+//
+// Being an internal package, pull can't be imported from common, so this
+// isn't embedded directly in common.DockerConfig - the executor's pull call
+// site builds one from DockerConfig's own LazyPull/Snapshotter fields.
+type Config struct {
+	// LazyPull opts into the stargz fast-path. It's a no-op unless
+	// Snapshotter is also set to SnapshotterStargz and the daemon confirms
+	// it's actually running with that snapshotter.
+	LazyPull bool `toml:"lazy_pull,omitempty" json:"lazy_pull,omitempty" long:"lazy-pull" env:"DOCKER_LAZY_PULL" description:"Skip prefetching images and let the containerd snapshotter lazily pull eStargz layers on demand"`
+	// Snapshotter is the containerd snapshotter the daemon is expected to be
+	// configured with.
+	Snapshotter Snapshotter `toml:"snapshotter,omitempty" json:"snapshotter,omitempty" long:"snapshotter" env:"DOCKER_SNAPSHOTTER" description:"Containerd snapshotter the Docker daemon is configured with (overlayfs or stargz)"`
+}
+
+// requested reports whether the user asked for lazy pulling at all. It
+// doesn't guarantee the daemon can actually honour it.
+func (c Config) requested() bool {
+	return c.LazyPull && c.Snapshotter == SnapshotterStargz
+}
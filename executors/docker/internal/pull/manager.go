@@ -0,0 +1,118 @@
+// Package pull implements the lazy-pull decision for eStargz images: given a
+// Config and a running daemon, Manager.PullOptions decides whether the
+// executor can skip its own prefetching `docker pull` and let the
+// containerd stargz snapshotter fetch the image on demand instead.
+//
+// common.DockerConfig carries the user-facing LazyPull/Snapshotter fields,
+// and executors/docker's pullImage is the call site: it builds a Config from
+// them and calls Manager.PullOptions before deciding whether to still run
+// its own `docker pull`.
+package pull
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/sirupsen/logrus"
+)
+
+// eStargzAnnotationPrefix is set on the per-layer descriptors of images built
+// with the eStargz format, e.g. "containerd.io/snapshot/stargz/toc.digest".
+const eStargzAnnotationPrefix = "containerd.io/snapshot/stargz"
+
+// Client is the Docker daemon API surface the Manager depends on.
+type Client interface {
+	infoClient
+}
+
+// Manager decides, for a given image and Config, whether the runner can skip
+// its own prefetching `docker pull` and let the daemon's snapshotter fetch
+// the image lazily, falling back to the caller doing a normal pull whenever
+// that isn't possible.
+type Manager struct {
+	logger    logrus.FieldLogger
+	client    Client
+	manifests ManifestInspector
+	prober    *snapshotterProber
+}
+
+func NewManager(logger logrus.FieldLogger, client Client, manifests ManifestInspector) *Manager {
+	return &Manager{
+		logger:    logger,
+		client:    client,
+		manifests: manifests,
+		prober:    newSnapshotterProber(client),
+	}
+}
+
+// PullOptions builds the types.ImagePullOptions to use for imageName, along
+// with whether the daemon will be able to lazily fetch it. encodedRegistryAuth
+// is the already base64-encoded credentials resolved from the runner's
+// DockerAuthConfig/credential helpers - it's always threaded through so
+// private eStargz images work the same as private regular images.
+//
+// When lazy is false, the caller should fall back to its normal prefetching
+// `docker pull` before creating the container.
+func (m *Manager) PullOptions(ctx context.Context, imageName string, cfg Config, encodedRegistryAuth string) (options types.ImagePullOptions, lazy bool) {
+	options = types.ImagePullOptions{RegistryAuth: encodedRegistryAuth}
+
+	log := m.logger.WithField("image", imageName)
+
+	if !cfg.requested() {
+		return options, false
+	}
+
+	supported, err := m.prober.supports(ctx, cfg.Snapshotter)
+	if err != nil {
+		log.WithError(err).Warning("Lazy pull: failed probing docker daemon snapshotter, falling back to a normal pull")
+
+		return options, false
+	}
+
+	if !supported {
+		log.Debug("Lazy pull: daemon isn't configured with the stargz snapshotter, falling back to a normal pull")
+
+		return options, false
+	}
+
+	isEStargz, err := m.isEStargz(ctx, imageName, encodedRegistryAuth)
+	if err != nil {
+		log.WithError(err).Warning("Lazy pull: failed inspecting image manifest, falling back to a normal pull")
+
+		return options, false
+	}
+
+	if !isEStargz {
+		log.Debug("Lazy pull: image isn't eStargz-formatted, falling back to a normal pull")
+
+		return options, false
+	}
+
+	log.Debug("Lazy pull: daemon and image both support stargz, skipping the prefetching pull")
+
+	return options, true
+}
+
+// isEStargz reports whether imageName's manifest carries the eStargz TOC
+// digest annotation on one of its layers. That annotation is set per-layer
+// by the tooling that builds eStargz images, not on the manifest's own
+// descriptor, so it can only be seen by reading the manifest itself - which
+// is why this goes through m.manifests instead of dockerd's
+// DistributionInspect API.
+func (m *Manager) isEStargz(ctx context.Context, imageName, encodedRegistryAuth string) (bool, error) {
+	layers, err := m.manifests.LayerDescriptors(ctx, imageName, encodedRegistryAuth)
+	if err != nil {
+		return false, err
+	}
+
+	for _, layer := range layers {
+		for annotation := range layer.Annotations {
+			if strings.HasPrefix(annotation, eStargzAnnotationPrefix) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,138 @@
+package pull
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/distribution"
+	"github.com/docker/docker/api/types"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDockerClient struct {
+	info    types.Info
+	infoErr error
+}
+
+func (c *fakeDockerClient) Info(_ context.Context) (types.Info, error) {
+	return c.info, c.infoErr
+}
+
+type fakeManifestInspector struct {
+	layers []distribution.Descriptor
+	err    error
+}
+
+func (f *fakeManifestInspector) LayerDescriptors(_ context.Context, _, _ string) ([]distribution.Descriptor, error) {
+	return f.layers, f.err
+}
+
+func containerdSnapshotterInfo(driver string) types.Info {
+	return types.Info{
+		Driver: driver,
+		DriverStatus: [][2]string{
+			{driverStatusTypeKey, driverStatusContainerdValue},
+		},
+	}
+}
+
+func eStargzLayers() []distribution.Descriptor {
+	return []distribution.Descriptor{
+		{MediaType: "application/vnd.oci.image.config.v1+json"},
+		{
+			MediaType: "application/vnd.oci.image.layer.v1.tar+gzip",
+			Annotations: map[string]string{
+				"containerd.io/snapshot/stargz/toc.digest": "sha256:deadbeef",
+			},
+		},
+	}
+}
+
+func TestManager_PullOptions(t *testing.T) {
+	tests := map[string]struct {
+		cfg          Config
+		client       *fakeDockerClient
+		manifests    *fakeManifestInspector
+		expectedLazy bool
+	}{
+		"lazy pull disabled": {
+			cfg:          Config{LazyPull: false, Snapshotter: SnapshotterStargz},
+			client:       &fakeDockerClient{info: containerdSnapshotterInfo("stargz")},
+			manifests:    &fakeManifestInspector{layers: eStargzLayers()},
+			expectedLazy: false,
+		},
+		"snapshotter not stargz": {
+			cfg:          Config{LazyPull: true, Snapshotter: SnapshotterOverlayFS},
+			client:       &fakeDockerClient{info: containerdSnapshotterInfo("overlayfs")},
+			manifests:    &fakeManifestInspector{layers: eStargzLayers()},
+			expectedLazy: false,
+		},
+		"daemon doesn't use containerd snapshotters at all": {
+			cfg:          Config{LazyPull: true, Snapshotter: SnapshotterStargz},
+			client:       &fakeDockerClient{info: types.Info{Driver: "overlay2"}},
+			manifests:    &fakeManifestInspector{layers: eStargzLayers()},
+			expectedLazy: false,
+		},
+		"daemon uses a different containerd snapshotter": {
+			cfg:          Config{LazyPull: true, Snapshotter: SnapshotterStargz},
+			client:       &fakeDockerClient{info: containerdSnapshotterInfo("overlayfs")},
+			manifests:    &fakeManifestInspector{layers: eStargzLayers()},
+			expectedLazy: false,
+		},
+		"image isn't eStargz": {
+			cfg:          Config{LazyPull: true, Snapshotter: SnapshotterStargz},
+			client:       &fakeDockerClient{info: containerdSnapshotterInfo("stargz")},
+			manifests:    &fakeManifestInspector{layers: []distribution.Descriptor{{MediaType: "application/vnd.oci.image.layer.v1.tar+gzip"}}},
+			expectedLazy: false,
+		},
+		"manifest without layer descriptors isn't eStargz": {
+			cfg:          Config{LazyPull: true, Snapshotter: SnapshotterStargz},
+			client:       &fakeDockerClient{info: containerdSnapshotterInfo("stargz")},
+			manifests:    &fakeManifestInspector{layers: nil},
+			expectedLazy: false,
+		},
+		"info probe fails": {
+			cfg:          Config{LazyPull: true, Snapshotter: SnapshotterStargz},
+			client:       &fakeDockerClient{infoErr: errors.New("connection refused")},
+			manifests:    &fakeManifestInspector{layers: eStargzLayers()},
+			expectedLazy: false,
+		},
+		"manifest inspection fails": {
+			cfg:          Config{LazyPull: true, Snapshotter: SnapshotterStargz},
+			client:       &fakeDockerClient{info: containerdSnapshotterInfo("stargz")},
+			manifests:    &fakeManifestInspector{err: errors.New("manifest unknown")},
+			expectedLazy: false,
+		},
+		"lazy pull fully supported": {
+			cfg:          Config{LazyPull: true, Snapshotter: SnapshotterStargz},
+			client:       &fakeDockerClient{info: containerdSnapshotterInfo("stargz")},
+			manifests:    &fakeManifestInspector{layers: eStargzLayers()},
+			expectedLazy: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			logger, _ := logrustest.NewNullLogger()
+			manager := NewManager(logger, tt.client, tt.manifests)
+
+			options, lazy := manager.PullOptions(context.Background(), "example.com/image:latest", tt.cfg, "dGVzdA==")
+
+			assert.Equal(t, tt.expectedLazy, lazy)
+			assert.Equal(t, "dGVzdA==", options.RegistryAuth, "registry auth should always be threaded through")
+		})
+	}
+}
+
+func TestManager_PullOptions_RegistryAuthThreadedOnFallback(t *testing.T) {
+	logger, _ := logrustest.NewNullLogger()
+	client := &fakeDockerClient{info: types.Info{Driver: "overlay2"}}
+	manager := NewManager(logger, client, &fakeManifestInspector{})
+
+	options, lazy := manager.PullOptions(context.Background(), "example.com/image:latest", Config{}, "Y3JlZHM=")
+	require.False(t, lazy)
+	assert.Equal(t, "Y3JlZHM=", options.RegistryAuth)
+}
@@ -0,0 +1,179 @@
+package pull
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/distribution/registry/client"
+	"github.com/docker/distribution/registry/client/auth"
+	"github.com/docker/distribution/registry/client/auth/challenge"
+	"github.com/docker/distribution/registry/client/transport"
+	"github.com/docker/docker/api/types"
+)
+
+// dockerHubDomain/dockerHubRegistryHost translate the conventional "docker.io"
+// image domain to the host that actually serves the v2 registry API, the
+// same mapping dockerd itself applies when resolving pull endpoints.
+const (
+	dockerHubDomain       = "docker.io"
+	dockerHubRegistryHost = "registry-1.docker.io"
+)
+
+// defaultManifestInspectorUserAgent identifies the runner to the registry
+// when no caller-specific user agent is given.
+const defaultManifestInspectorUserAgent = "gitlab-runner"
+
+// ManifestInspector fetches the layer descriptors of an image's manifest.
+// dockerd's own DistributionInspect API only ever returns the manifest's own
+// descriptor - never the per-layer descriptors that carry the eStargz TOC
+// digest annotation - so the Manager goes through this instead, talking to
+// the registry directly the same way `docker manifest inspect` does.
+type ManifestInspector interface {
+	LayerDescriptors(ctx context.Context, imageName, encodedRegistryAuth string) ([]distribution.Descriptor, error)
+}
+
+type registryManifestInspector struct {
+	userAgent string
+}
+
+// NewRegistryManifestInspector returns a ManifestInspector that talks to the
+// image's registry directly instead of going through dockerd.
+func NewRegistryManifestInspector(userAgent string) ManifestInspector {
+	if userAgent == "" {
+		userAgent = defaultManifestInspectorUserAgent
+	}
+
+	return &registryManifestInspector{userAgent: userAgent}
+}
+
+func (i *registryManifestInspector) LayerDescriptors(
+	ctx context.Context,
+	imageName, encodedRegistryAuth string,
+) ([]distribution.Descriptor, error) {
+	ref, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return nil, fmt.Errorf("parsing image reference: %w", err)
+	}
+
+	authConfig, err := decodeRegistryAuth(encodedRegistryAuth)
+	if err != nil {
+		return nil, fmt.Errorf("decoding registry auth: %w", err)
+	}
+
+	repo, err := i.repository(ref, authConfig)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to registry: %w", err)
+	}
+
+	manifest, err := getManifest(ctx, repo, ref)
+	if err != nil {
+		return nil, fmt.Errorf("fetching manifest: %w", err)
+	}
+
+	deserialized, ok := manifest.(*schema2.DeserializedManifest)
+	if !ok {
+		return nil, fmt.Errorf("%s does not resolve to a single-platform schema2 manifest", imageName)
+	}
+
+	return deserialized.References(), nil
+}
+
+func (i *registryManifestInspector) repository(ref reference.Named, authConfig types.AuthConfig) (distribution.Repository, error) {
+	baseURL := registryBaseURL(ref)
+
+	userAgentModifier := transport.NewHeaderRequestModifier(http.Header{"User-Agent": []string{i.userAgent}})
+	pingTransport := transport.NewTransport(http.DefaultTransport, userAgentModifier)
+	challengeManager := challenge.NewSimpleManager()
+
+	resp, err := (&http.Client{Transport: pingTransport}).Get(baseURL + "/v2/")
+	if err != nil {
+		return nil, fmt.Errorf("pinging registry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := challengeManager.AddResponse(resp); err != nil {
+		return nil, fmt.Errorf("reading registry authentication challenge: %w", err)
+	}
+
+	creds := staticCredentialStore{username: authConfig.Username, password: authConfig.Password}
+	authorizer := auth.NewAuthorizer(
+		challengeManager,
+		auth.NewTokenHandler(pingTransport, creds, reference.Path(ref), "pull"),
+		auth.NewBasicHandler(creds),
+	)
+
+	repoTransport := transport.NewTransport(http.DefaultTransport, userAgentModifier, authorizer)
+
+	return client.NewRepository(ref, baseURL, repoTransport)
+}
+
+func registryBaseURL(ref reference.Named) string {
+	domain := reference.Domain(ref)
+	if domain == dockerHubDomain {
+		domain = dockerHubRegistryHost
+	}
+
+	return "https://" + domain
+}
+
+func getManifest(ctx context.Context, repo distribution.Repository, ref reference.Named) (distribution.Manifest, error) {
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if tagged, ok := ref.(reference.NamedTagged); ok {
+		return manifests.Get(ctx, "", distribution.WithTag(tagged.Tag()))
+	}
+
+	if digested, ok := ref.(reference.Canonical); ok {
+		return manifests.Get(ctx, digested.Digest())
+	}
+
+	return manifests.Get(ctx, "", distribution.WithTag("latest"))
+}
+
+// staticCredentialStore implements auth.CredentialStore with a single fixed
+// set of credentials - the registry auth the caller already resolved via
+// DockerAuthConfig/credential helpers.
+type staticCredentialStore struct {
+	username string
+	password string
+}
+
+func (s staticCredentialStore) Basic(*url.URL) (string, string) {
+	return s.username, s.password
+}
+
+func (s staticCredentialStore) RefreshToken(*url.URL, string) string {
+	return ""
+}
+
+func (s staticCredentialStore) SetRefreshToken(*url.URL, string, string) {}
+
+// decodeRegistryAuth decodes the base64-encoded X-Registry-Auth payload the
+// rest of the Docker executor already threads through for normal pulls.
+func decodeRegistryAuth(encoded string) (types.AuthConfig, error) {
+	var cfg types.AuthConfig
+	if encoded == "" {
+		return cfg, nil
+	}
+
+	data, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,58 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+)
+
+// driverStatus is the key/value pair dockerd reports in Info.DriverStatus
+// when the graph driver is actually a thin shim over a containerd
+// snapshotter.
+const (
+	driverStatusTypeKey         = "driver-type"
+	driverStatusContainerdValue = "io.containerd.snapshotter.v1"
+)
+
+// infoClient is the subset of the Docker API client the prober needs.
+type infoClient interface {
+	Info(ctx context.Context) (types.Info, error)
+}
+
+// snapshotterProber inspects a running Docker daemon to find out which
+// containerd snapshotter, if any, it has been configured with.
+type snapshotterProber struct {
+	client infoClient
+}
+
+func newSnapshotterProber(client infoClient) *snapshotterProber {
+	return &snapshotterProber{client: client}
+}
+
+// supports reports whether the daemon is backed by containerd and registered
+// with the given snapshotter. A false result with a nil error means the
+// daemon simply doesn't support it, which callers should treat as a signal to
+// fall back to a normal pull rather than as a failure.
+func (p *snapshotterProber) supports(ctx context.Context, snapshotter Snapshotter) (bool, error) {
+	info, err := p.client.Info(ctx)
+	if err != nil {
+		return false, fmt.Errorf("inspecting docker daemon info: %w", err)
+	}
+
+	if !usesContainerdSnapshotter(info) {
+		return false, nil
+	}
+
+	return Snapshotter(info.Driver) == snapshotter, nil
+}
+
+func usesContainerdSnapshotter(info types.Info) bool {
+	for _, status := range info.DriverStatus {
+		if len(status) == 2 && status[0] == driverStatusTypeKey && status[1] == driverStatusContainerdValue {
+			return true
+		}
+	}
+
+	return false
+}
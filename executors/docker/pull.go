@@ -0,0 +1,51 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/docker/docker/api/types"
+	"github.com/sirupsen/logrus"
+
+	"gitlab.com/gitlab-org/gitlab-runner/common"
+	"gitlab.com/gitlab-org/gitlab-runner/executors/docker/internal/pull"
+)
+
+// imagePullClient is the subset of the Docker API client pullImage needs -
+// pull.Client to probe the daemon's snapshotter, plus the actual pull call.
+type imagePullClient interface {
+	pull.Client
+	ImagePull(ctx context.Context, refStr string, options types.ImagePullOptions) (io.ReadCloser, error)
+}
+
+// pullImage fetches imageName, consulting a pull.Manager to find out whether
+// the daemon can lazily pull it instead of prefetching it here. When it can,
+// the executor's own `docker pull` is skipped entirely and the daemon fetches
+// eStargz layers on demand as the container starts.
+func pullImage(
+	ctx context.Context,
+	logger logrus.FieldLogger,
+	client imagePullClient,
+	manifests pull.ManifestInspector,
+	cfg common.DockerConfig,
+	imageName, encodedRegistryAuth string,
+) error {
+	manager := pull.NewManager(logger, client, manifests)
+	pullCfg := pull.Config{LazyPull: cfg.LazyPull, Snapshotter: pull.Snapshotter(cfg.Snapshotter)}
+
+	options, lazy := manager.PullOptions(ctx, imageName, pullCfg, encodedRegistryAuth)
+	if lazy {
+		return nil
+	}
+
+	reader, err := client.ImagePull(ctx, imageName, options)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	_, err = io.Copy(ioutil.Discard, reader)
+
+	return err
+}
@@ -0,0 +1,133 @@
+package ca_chain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// defaultCacheMaxEntries bounds how many fetched intermediates the on-disk
+// cache keeps around, so a long-lived runner config dir doesn't grow
+// unbounded across many distinct CA chains.
+const defaultCacheMaxEntries = 256
+
+// Cache stores the raw, still-encoded bytes of fetched issuer certificates,
+// keyed by cacheKey, so repeated register/verify runs against the same CA
+// don't re-download the same intermediates.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, data []byte)
+}
+
+// cacheKey derives a stable cache key from the URL a certificate was fetched
+// from and the fetching certificate's AuthorityKeyId, so two different
+// certificates that happen to point at the same issuer URL but were signed
+// by different keys don't collide.
+func cacheKey(issuerURL string, authorityKeyID []byte) string {
+	h := sha256.New()
+	h.Write([]byte(issuerURL))
+	h.Write(authorityKeyID)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// memoryCache is an in-process, non-persistent Cache. It's the resolver's
+// default and what tests should reach for.
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+// NewMemoryCache returns a Cache that only lives for the lifetime of the
+// process.
+func NewMemoryCache() Cache {
+	return &memoryCache{entries: map[string][]byte{}}
+}
+
+func (c *memoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, ok := c.entries[key]
+
+	return data, ok
+}
+
+func (c *memoryCache) Set(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = data
+}
+
+// fileCache persists fetched certificates as individual files under a
+// directory - typically the runner's config dir - so the cache survives
+// across separate `register`/`verify` invocations.
+type fileCache struct {
+	mu         sync.Mutex
+	dir        string
+	maxEntries int
+}
+
+// NewFileCache returns a Cache persisted under dir, bounded to maxEntries
+// entries; once the limit is reached the oldest entry (by file modification
+// time) is evicted to make room for the new one. dir is created if it
+// doesn't already exist.
+func NewFileCache(dir string, maxEntries int) (Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	if maxEntries <= 0 {
+		maxEntries = defaultCacheMaxEntries
+	}
+
+	return &fileCache{dir: dir, maxEntries: maxEntries}, nil
+}
+
+func (c *fileCache) path(key string) string {
+	return filepath.Join(c.dir, key)
+}
+
+func (c *fileCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+func (c *fileCache) Set(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := ioutil.WriteFile(c.path(key), data, 0600); err != nil {
+		return
+	}
+
+	c.evictOldest()
+}
+
+func (c *fileCache) evictOldest() {
+	entries, err := ioutil.ReadDir(c.dir)
+	if err != nil || len(entries) <= c.maxEntries {
+		return
+	}
+
+	oldest := entries[0]
+	for _, entry := range entries[1:] {
+		if entry.ModTime().Before(oldest.ModTime()) {
+			oldest = entry
+		}
+	}
+
+	_ = os.Remove(filepath.Join(c.dir, oldest.Name()))
+}
@@ -0,0 +1,66 @@
+package ca_chain
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache_GetSet(t *testing.T) {
+	c := NewMemoryCache()
+
+	_, ok := c.Get("missing")
+	assert.False(t, ok)
+
+	c.Set("key", []byte("data"))
+
+	data, ok := c.Get("key")
+	require.True(t, ok)
+	assert.Equal(t, []byte("data"), data)
+}
+
+func TestFileCache_GetSet(t *testing.T) {
+	c, err := NewFileCache(t.TempDir(), defaultCacheMaxEntries)
+	require.NoError(t, err)
+
+	_, ok := c.Get("missing")
+	assert.False(t, ok)
+
+	c.Set("key", []byte("data"))
+
+	data, ok := c.Get("key")
+	require.True(t, ok)
+	assert.Equal(t, []byte("data"), data)
+}
+
+func TestFileCache_EvictsOldestBeyondMaxEntries(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewFileCache(dir, 2)
+	require.NoError(t, err)
+
+	c.Set("first", []byte("1"))
+	c.Set("second", []byte("2"))
+	c.Set("third", []byte("3"))
+
+	_, ok := c.Get("first")
+	assert.False(t, ok, "oldest entry should have been evicted")
+
+	_, ok = c.Get("third")
+	assert.True(t, ok)
+}
+
+func TestCacheKey_DiffersByAuthorityKeyID(t *testing.T) {
+	a := cacheKey("http://example.com/issuer.crt", []byte{1})
+	b := cacheKey("http://example.com/issuer.crt", []byte{2})
+
+	assert.NotEqual(t, a, b)
+}
+
+func TestNewFileCache_CreatesDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "cache")
+
+	_, err := NewFileCache(dir, defaultCacheMaxEntries)
+	require.NoError(t, err)
+}
@@ -0,0 +1,76 @@
+package ca_chain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLDAPURL(t *testing.T) {
+	tests := map[string]struct {
+		url               string
+		expectedDN        string
+		expectedAttribute string
+		expectedAddr      string
+	}{
+		"no query uses the default attribute": {
+			url:               "ldap://ca.example.com/cn=Example%20CA,dc=example,dc=com",
+			expectedDN:        "cn=Example CA,dc=example,dc=com",
+			expectedAttribute: ldapDefaultAttribute,
+			expectedAddr:      "ldap://ca.example.com",
+		},
+		"custom attribute": {
+			url:               "ldap://ca.example.com/cn=Example%20CA,dc=example,dc=com?crossCertificatePair",
+			expectedDN:        "cn=Example CA,dc=example,dc=com",
+			expectedAttribute: "crossCertificatePair",
+			expectedAddr:      "ldap://ca.example.com",
+		},
+		"binary suffix is stripped": {
+			url:               "ldap://ca.example.com/cn=Example%20CA,dc=example,dc=com?cACertificate;binary",
+			expectedDN:        "cn=Example CA,dc=example,dc=com",
+			expectedAttribute: "cACertificate",
+			expectedAddr:      "ldap://ca.example.com",
+		},
+		"only the first attribute of a comma-separated list is used": {
+			url:               "ldap://ca.example.com/cn=Example%20CA,dc=example,dc=com?cACertificate,certificateRevocationList",
+			expectedDN:        "cn=Example CA,dc=example,dc=com",
+			expectedAttribute: "cACertificate",
+			expectedAddr:      "ldap://ca.example.com",
+		},
+		"scope and filter sections are ignored": {
+			url:               "ldap://ca.example.com/cn=Example%20CA,dc=example,dc=com?cACertificate?base?(objectClass=*)",
+			expectedDN:        "cn=Example CA,dc=example,dc=com",
+			expectedAttribute: "cACertificate",
+			expectedAddr:      "ldap://ca.example.com",
+		},
+		"ldaps scheme and explicit port are preserved": {
+			url:               "ldaps://ca.example.com:636/cn=Example%20CA,dc=example,dc=com",
+			expectedDN:        "cn=Example CA,dc=example,dc=com",
+			expectedAttribute: ldapDefaultAttribute,
+			expectedAddr:      "ldaps://ca.example.com:636",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dn, attribute, addr, err := parseLDAPURL(tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedDN, dn)
+			assert.Equal(t, tt.expectedAttribute, attribute)
+			assert.Equal(t, tt.expectedAddr, addr)
+		})
+	}
+}
+
+func TestParseLDAPURL_InvalidURL(t *testing.T) {
+	_, _, _, err := parseLDAPURL("://not-a-url")
+	assert.Error(t, err)
+}
+
+func TestLDAPFetcher_Fetch_InvalidURL(t *testing.T) {
+	f := newLDAPFetcher()
+
+	_, err := f.Fetch("://not-a-url")
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package ca_chain
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// chainResolutionMetrics exposes how often the urlResolver's cache is
+// actually saving a network round-trip, and how long the round-trips that do
+// happen take, so operators can see how expensive chain resolution is on a
+// running fleet of runners.
+type chainResolutionMetrics struct {
+	cacheHits     prometheus.Counter
+	cacheMisses   prometheus.Counter
+	fetchDuration prometheus.Histogram
+}
+
+// chainResolutionMetricsOnce guards the metrics below: every urlResolver in
+// this process shares the same collectors, registered with the default
+// registry exactly once, rather than each constructing (and trying to
+// register) its own collectors under the same metric names.
+var (
+	chainResolutionMetricsOnce   sync.Once
+	sharedChainResolutionMetrics *chainResolutionMetrics
+)
+
+func newChainResolutionMetrics() *chainResolutionMetrics {
+	chainResolutionMetricsOnce.Do(func() {
+		sharedChainResolutionMetrics = &chainResolutionMetrics{
+			cacheHits: promauto.NewCounter(prometheus.CounterOpts{
+				Name: "gitlab_runner_ca_chain_resolver_cache_hits_total",
+				Help: "Total number of intermediate certificate cache hits while resolving CA chains.",
+			}),
+			cacheMisses: promauto.NewCounter(prometheus.CounterOpts{
+				Name: "gitlab_runner_ca_chain_resolver_cache_misses_total",
+				Help: "Total number of intermediate certificate cache misses while resolving CA chains.",
+			}),
+			fetchDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+				Name:    "gitlab_runner_ca_chain_resolver_fetch_duration_seconds",
+				Help:    "Time spent fetching an issuer certificate from a remote source.",
+				Buckets: prometheus.DefBuckets,
+			}),
+		}
+	})
+
+	return sharedChainResolutionMetrics
+}
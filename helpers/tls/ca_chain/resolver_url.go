@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -19,7 +20,10 @@ import (
 const defaultURLResolverLoopLimit = 15
 const defaultURLResolverFetchTimeout = 15 * time.Second
 
-type fetcher interface {
+// Fetcher retrieves the raw bytes behind a single issuer URL. Implementations
+// are scheme-specific: httpFetcher handles `http(s)://`, ldapFetcher handles
+// `ldap(s)://`.
+type Fetcher interface {
 	Fetch(url string) ([]byte, error)
 }
 
@@ -54,21 +58,72 @@ func (f *httpFetcher) Fetch(url string) ([]byte, error) {
 
 type decoder func(data []byte) (*x509.Certificate, error)
 
+// urlResolverOption configures a urlResolver at construction time, so callers
+// - `commands/register`, the S3/cache clients, tests - can plug in their own
+// cache and fetcher implementations without the resolver needing to know
+// about any of them.
+type urlResolverOption func(*urlResolver)
+
+// WithCache makes the resolver consult c before fetching an issuer
+// certificate over the network, and populate it once a fetch succeeds.
+// Without this option the resolver uses an in-process memory cache, so
+// fetched certificates are only reused for the lifetime of the process.
+func WithCache(c Cache) urlResolverOption {
+	return func(r *urlResolver) {
+		r.cache = c
+	}
+}
+
+// WithFetcher registers f as the Fetcher used for URLs with the given
+// scheme (e.g. "http", "ldap"), replacing the resolver's default for that
+// scheme.
+func WithFetcher(scheme string, f Fetcher) urlResolverOption {
+	return func(r *urlResolver) {
+		r.fetchers[scheme] = f
+	}
+}
+
+// WithLoopLimit overrides the resolver's loop limit: it follows at most
+// limit-1 issuer hops before giving up, guarding against cyclical or
+// absurdly long chains.
+func WithLoopLimit(limit int) urlResolverOption {
+	return func(r *urlResolver) {
+		r.loopLimit = limit
+	}
+}
+
 type urlResolver struct {
-	logger  logrus.FieldLogger
-	fetcher fetcher
-	decoder decoder
+	logger   logrus.FieldLogger
+	fetchers map[string]Fetcher
+	decoder  decoder
+	cache    Cache
+	metrics  *chainResolutionMetrics
 
 	loopLimit int
 }
 
-func newURLResolver(logger logrus.FieldLogger) resolver {
-	return &urlResolver{
-		logger:    logger,
-		fetcher:   newHTTPFetcher(defaultURLResolverFetchTimeout),
+func newURLResolver(logger logrus.FieldLogger, opts ...urlResolverOption) resolver {
+	httpFetcher := newHTTPFetcher(defaultURLResolverFetchTimeout)
+
+	r := &urlResolver{
+		logger: logger,
+		fetchers: map[string]Fetcher{
+			"http":  httpFetcher,
+			"https": httpFetcher,
+			"ldap":  newLDAPFetcher(),
+			"ldaps": newLDAPFetcher(),
+		},
 		decoder:   decodeCertificate,
+		cache:     NewMemoryCache(),
+		metrics:   newChainResolutionMetrics(),
 		loopLimit: defaultURLResolverLoopLimit,
 	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
 func (r *urlResolver) Resolve(certs []*x509.Certificate) ([]*x509.Certificate, error) {
@@ -90,7 +145,7 @@ func (r *urlResolver) Resolve(certs []*x509.Certificate) ([]*x509.Certificate, e
 		certificate := certs[len(certs)-1]
 		log := prepareCertificateLogger(r.logger, certificate)
 
-		if certificate.IssuingCertificateURL == nil {
+		if len(certificate.IssuingCertificateURL) < 1 {
 			log.Debug("Certificate doesn't provide parent URL: exiting the loop")
 			break
 		}
@@ -111,33 +166,79 @@ func (r *urlResolver) Resolve(certs []*x509.Certificate) ([]*x509.Certificate, e
 	return certs, nil
 }
 
+// fetchIssuerCertificate walks every URL in the certificate's
+// IssuingCertificateURL - RFC 5280 allows more than one, and real-world CAs
+// commonly publish an HTTP mirror alongside an LDAP one - trying each in turn
+// and falling through to the next on failure.
 func (r *urlResolver) fetchIssuerCertificate(cert *x509.Certificate) (*x509.Certificate, error) {
 	log := prepareCertificateLogger(r.logger, cert).
 		WithField("method", "fetchIssuerCertificate")
 
-	issuerURL := cert.IssuingCertificateURL[0]
+	var lastErr error
+	for _, issuerURL := range cert.IssuingCertificateURL {
+		newCert, err := r.fetchFromURL(issuerURL, cert.AuthorityKeyId)
+		if err != nil {
+			log.
+				WithError(err).
+				WithField("issuerURL", issuerURL).
+				Warning("Fetching issuer certificate failed, trying the next URL if any")
+
+			lastErr = err
+			continue
+		}
+
+		preparePrefixedCertificateLogger(log, newCert, "newCert").
+			Debug("Appending the certificate to the chain")
+
+		return newCert, nil
+	}
 
-	data, err := r.fetcher.Fetch(issuerURL)
+	return nil, fmt.Errorf("fetching issuer certificate from all provided URLs failed: %w", lastErr)
+}
+
+func (r *urlResolver) fetchFromURL(issuerURL string, authorityKeyID []byte) (*x509.Certificate, error) {
+	key := cacheKey(issuerURL, authorityKeyID)
+
+	if data, ok := r.cache.Get(key); ok {
+		r.metrics.cacheHits.Inc()
+
+		return r.decoder(data)
+	}
+
+	r.metrics.cacheMisses.Inc()
+
+	f, err := r.fetcherFor(issuerURL)
 	if err != nil {
-		log.
-			WithError(err).
-			WithField("issuerURL", issuerURL).
-			Warning("Remote certificate fetching error")
+		return nil, err
+	}
 
-		return nil, fmt.Errorf("remote fetch failure: %v", err)
+	start := time.Now()
+	data, err := f.Fetch(issuerURL)
+	r.metrics.fetchDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("remote fetch failure: %w", err)
 	}
 
 	newCert, err := r.decoder(data)
 	if err != nil {
-		log.
-			WithError(err).
-			Warning("Certificate decoding error")
-
-		return nil, fmt.Errorf("decoding failure: %v", err)
+		return nil, fmt.Errorf("decoding failure: %w", err)
 	}
 
-	preparePrefixedCertificateLogger(log, newCert, "newCert").
-		Debug("Appending the certificate to the chain")
+	r.cache.Set(key, data)
 
 	return newCert, nil
-}
\ No newline at end of file
+}
+
+func (r *urlResolver) fetcherFor(issuerURL string) (Fetcher, error) {
+	parsed, err := url.Parse(issuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing issuer URL: %w", err)
+	}
+
+	f, ok := r.fetchers[parsed.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("no fetcher registered for scheme %q", parsed.Scheme)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,145 @@
+package ca_chain
+
+import (
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFetcher struct {
+	data []byte
+	err  error
+}
+
+func (f *fakeFetcher) Fetch(_ string) ([]byte, error) {
+	return f.data, f.err
+}
+
+func testDecoder(data []byte) (*x509.Certificate, error) {
+	if len(data) == 0 {
+		return nil, errors.New("no data to decode")
+	}
+
+	return &x509.Certificate{Raw: data}, nil
+}
+
+func newTestURLResolver(opts ...urlResolverOption) *urlResolver {
+	logger, _ := logrustest.NewNullLogger()
+	r := newURLResolver(logger, opts...).(*urlResolver)
+	r.decoder = testDecoder
+
+	return r
+}
+
+func TestURLResolver_FetchIssuerCertificate_FallsThroughToNextURL(t *testing.T) {
+	r := newTestURLResolver(
+		WithFetcher("http", &fakeFetcher{err: errors.New("connection refused")}),
+		WithFetcher("ldap", &fakeFetcher{data: []byte("issuer-cert")}),
+	)
+
+	cert := &x509.Certificate{
+		IssuingCertificateURL: []string{"http://ca.example.com/issuer.crt", "ldap://ca.example.com/issuer"},
+	}
+
+	newCert, err := r.fetchIssuerCertificate(cert)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("issuer-cert"), newCert.Raw)
+}
+
+func TestURLResolver_FetchIssuerCertificate_AllURLsFail(t *testing.T) {
+	r := newTestURLResolver(
+		WithFetcher("http", &fakeFetcher{err: errors.New("404 not found")}),
+	)
+
+	cert := &x509.Certificate{
+		IssuingCertificateURL: []string{"http://ca.example.com/issuer.crt"},
+	}
+
+	_, err := r.fetchIssuerCertificate(cert)
+	assert.Error(t, err)
+}
+
+func TestURLResolver_FetcherFor(t *testing.T) {
+	httpFetcher := &fakeFetcher{}
+	r := newTestURLResolver(WithFetcher("http", httpFetcher))
+
+	tests := map[string]struct {
+		url         string
+		expectedErr bool
+	}{
+		"registered scheme":   {url: "http://ca.example.com/issuer.crt"},
+		"unregistered scheme": {url: "ftp://ca.example.com/issuer.crt", expectedErr: true},
+		"unparsable url":      {url: "://ca.example.com/issuer.crt", expectedErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := r.fetcherFor(tt.url)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Same(t, httpFetcher, f)
+		})
+	}
+}
+
+func TestURLResolver_FetchFromURL_CacheHitSkipsFetcher(t *testing.T) {
+	cache := NewMemoryCache()
+	cache.Set(cacheKey("http://ca.example.com/issuer.crt", nil), []byte("cached-cert"))
+
+	r := newTestURLResolver(
+		WithCache(cache),
+		WithFetcher("http", &fakeFetcher{err: errors.New("fetcher should not be called on a cache hit")}),
+	)
+
+	hitsBefore := testutil.ToFloat64(r.metrics.cacheHits)
+	missesBefore := testutil.ToFloat64(r.metrics.cacheMisses)
+
+	cert, err := r.fetchFromURL("http://ca.example.com/issuer.crt", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cached-cert"), cert.Raw)
+	assert.Equal(t, hitsBefore+1, testutil.ToFloat64(r.metrics.cacheHits))
+	assert.Equal(t, missesBefore, testutil.ToFloat64(r.metrics.cacheMisses))
+}
+
+func TestURLResolver_FetchFromURL_CacheMissPopulatesCache(t *testing.T) {
+	cache := NewMemoryCache()
+	r := newTestURLResolver(WithCache(cache), WithFetcher("http", &fakeFetcher{data: []byte("fetched-cert")}))
+
+	missesBefore := testutil.ToFloat64(r.metrics.cacheMisses)
+
+	cert, err := r.fetchFromURL("http://ca.example.com/issuer.crt", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("fetched-cert"), cert.Raw)
+	assert.Equal(t, missesBefore+1, testutil.ToFloat64(r.metrics.cacheMisses))
+
+	cached, ok := cache.Get(cacheKey("http://ca.example.com/issuer.crt", nil))
+	require.True(t, ok)
+	assert.Equal(t, []byte("fetched-cert"), cached)
+}
+
+func TestURLResolver_FetchFromURL_FetchFailureIsNotCached(t *testing.T) {
+	cache := NewMemoryCache()
+	r := newTestURLResolver(WithCache(cache), WithFetcher("http", &fakeFetcher{err: errors.New("connection refused")}))
+
+	_, err := r.fetchFromURL("http://ca.example.com/issuer.crt", nil)
+	assert.Error(t, err)
+
+	_, ok := cache.Get(cacheKey("http://ca.example.com/issuer.crt", nil))
+	assert.False(t, ok)
+}
+
+func TestURLResolver_FetchFromURL_UnknownSchemeErrors(t *testing.T) {
+	r := newTestURLResolver()
+
+	_, err := r.fetchFromURL("ftp://ca.example.com/issuer.crt", nil)
+	assert.Error(t, err)
+}
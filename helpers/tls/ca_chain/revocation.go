@@ -0,0 +1,164 @@
+package ca_chain
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RevocationMode controls how the revocation stage reacts to a confirmed
+// revocation, and to a checker it couldn't reach at all.
+type RevocationMode string
+
+const (
+	// RevocationModeOff skips revocation checking entirely.
+	RevocationModeOff RevocationMode = "off"
+	// RevocationModeSoft hard-fails the chain on a confirmed Revoked
+	// status, but logs and moves on when a checker can't be reached -
+	// mirroring how browsers treat OCSP/CRL network failures.
+	RevocationModeSoft RevocationMode = "soft"
+	// RevocationModeStrict additionally hard-fails the chain when a
+	// checker errors out, rather than only on a confirmed revocation.
+	RevocationModeStrict RevocationMode = "strict"
+)
+
+// RevokedCertificateError is returned when a certificate in the chain has
+// been confirmed revoked by either OCSP or a CRL.
+type RevokedCertificateError struct {
+	Serial *big.Int
+	Reason string
+}
+
+func (e *RevokedCertificateError) Error() string {
+	return fmt.Sprintf("certificate with serial %s is revoked: %s", e.Serial, e.Reason)
+}
+
+// revocationValidationError marks an error as coming from a response the
+// checker actually received but failed to validate - a forged/wrong-issuer
+// CRL signature, a tampered OCSP response - as opposed to simply failing to
+// reach the responder. It always hard-fails the chain, even in
+// RevocationModeSoft, mirroring how browsers treat a bad OCSP/CRL signature
+// versus a network timeout.
+type revocationValidationError struct {
+	err error
+}
+
+func newRevocationValidationError(err error) error {
+	return &revocationValidationError{err: err}
+}
+
+func (e *revocationValidationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *revocationValidationError) Unwrap() error {
+	return e.err
+}
+
+// revocationStatus is the outcome of checking a single certificate against a
+// single revocation source.
+type revocationStatus int
+
+const (
+	revocationStatusUnknown revocationStatus = iota
+	revocationStatusGood
+	revocationStatusRevoked
+)
+
+// revocationChecker checks whether cert, issued by issuer, has been revoked.
+// A revocationStatusUnknown result with a nil error means the source simply
+// doesn't apply (e.g. the certificate has no OCSP server), not that the
+// check failed.
+type revocationChecker interface {
+	Check(cert, issuer *x509.Certificate) (revocationStatus, error)
+}
+
+// revocationResolverOption configures a revocationResolver at construction
+// time, primarily so tests can swap in fake checkers.
+type revocationResolverOption func(*revocationResolver)
+
+// WithRevocationCheckers replaces the resolver's default OCSP+CRL checkers.
+func WithRevocationCheckers(checkers ...revocationChecker) revocationResolverOption {
+	return func(r *revocationResolver) {
+		r.checkers = checkers
+	}
+}
+
+// revocationResolver runs after urlResolver has assembled the full chain,
+// confirming that none of the leaf or intermediate certificates have been
+// revoked by their issuer. It implements the same resolver interface as
+// urlResolver so it can be chained after it in the Resolver pipeline.
+type revocationResolver struct {
+	logger   logrus.FieldLogger
+	mode     RevocationMode
+	checkers []revocationChecker
+}
+
+func newRevocationResolver(logger logrus.FieldLogger, mode RevocationMode, opts ...revocationResolverOption) resolver {
+	r := &revocationResolver{
+		logger: logger,
+		mode:   mode,
+		checkers: []revocationChecker{
+			newOCSPChecker(),
+			newCRLChecker(),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+func (r *revocationResolver) Resolve(certs []*x509.Certificate) ([]*x509.Certificate, error) {
+	if r.mode == RevocationModeOff {
+		return certs, nil
+	}
+
+	for i := 0; i < len(certs)-1; i++ {
+		cert := certs[i]
+		issuer := certs[i+1]
+
+		log := prepareCertificateLogger(r.logger, cert).WithField("method", "revocationResolver.Resolve")
+
+		if err := r.checkCertificate(cert, issuer, log); err != nil {
+			return nil, err
+		}
+	}
+
+	return certs, nil
+}
+
+// checkCertificate runs cert through each configured checker in turn,
+// short-circuiting as soon as one of them returns a definitive Good or
+// Revoked status.
+func (r *revocationResolver) checkCertificate(cert, issuer *x509.Certificate, log logrus.FieldLogger) error {
+	for _, checker := range r.checkers {
+		status, err := checker.Check(cert, issuer)
+		if err != nil {
+			log.WithError(err).Warning("Revocation checker failed")
+
+			var validationErr *revocationValidationError
+			if r.mode == RevocationModeStrict || errors.As(err, &validationErr) {
+				return fmt.Errorf("revocation check failed: %w", err)
+			}
+
+			continue
+		}
+
+		switch status {
+		case revocationStatusRevoked:
+			return &RevokedCertificateError{Serial: cert.SerialNumber, Reason: "certificate revoked by issuer"}
+		case revocationStatusGood:
+			return nil
+		case revocationStatusUnknown:
+			continue
+		}
+	}
+
+	return nil
+}
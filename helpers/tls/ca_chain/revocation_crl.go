@@ -0,0 +1,54 @@
+package ca_chain
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// crlChecker confirms revocation via the certificate's CRL distribution
+// point.
+type crlChecker struct {
+	client *http.Client
+}
+
+func newCRLChecker() *crlChecker {
+	return &crlChecker{client: &http.Client{Timeout: defaultURLResolverFetchTimeout}}
+}
+
+func (c *crlChecker) Check(cert, issuer *x509.Certificate) (revocationStatus, error) {
+	if len(cert.CRLDistributionPoints) == 0 {
+		return revocationStatusUnknown, nil
+	}
+
+	resp, err := c.client.Get(cert.CRLDistributionPoints[0])
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return revocationStatusUnknown, fmt.Errorf("fetching CRL: %w", err)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return revocationStatusUnknown, fmt.Errorf("reading CRL response: %w", err)
+	}
+
+	list, err := x509.ParseCRL(body)
+	if err != nil {
+		return revocationStatusUnknown, fmt.Errorf("parsing CRL: %w", err)
+	}
+
+	if err := issuer.CheckCRLSignature(list); err != nil {
+		return revocationStatusUnknown, newRevocationValidationError(fmt.Errorf("verifying CRL signature: %w", err))
+	}
+
+	for _, revoked := range list.TBSCertList.RevokedCertificates {
+		if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+			return revocationStatusRevoked, nil
+		}
+	}
+
+	return revocationStatusGood, nil
+}
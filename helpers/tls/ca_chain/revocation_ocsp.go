@@ -0,0 +1,102 @@
+package ca_chain
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// ocspChecker confirms revocation via the certificate's OCSP responder,
+// caching responses for as long as their NextUpdate says they're valid.
+type ocspChecker struct {
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]*ocsp.Response
+}
+
+func newOCSPChecker() *ocspChecker {
+	return &ocspChecker{
+		client: &http.Client{Timeout: defaultURLResolverFetchTimeout},
+		cache:  map[string]*ocsp.Response{},
+	}
+}
+
+func (c *ocspChecker) Check(cert, issuer *x509.Certificate) (revocationStatus, error) {
+	if len(cert.OCSPServer) == 0 {
+		return revocationStatusUnknown, nil
+	}
+
+	key := cert.SerialNumber.String()
+
+	if cached, ok := c.cachedResponse(key); ok {
+		return ocspResponseStatus(cached.Status), nil
+	}
+
+	req, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return revocationStatusUnknown, fmt.Errorf("building OCSP request: %w", err)
+	}
+
+	resp, err := c.client.Post(cert.OCSPServer[0], "application/ocsp-request", bytes.NewReader(req))
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return revocationStatusUnknown, fmt.Errorf("posting OCSP request: %w", err)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return revocationStatusUnknown, fmt.Errorf("reading OCSP response: %w", err)
+	}
+
+	parsed, err := ocsp.ParseResponseForCert(body, cert, issuer)
+	if err != nil {
+		return revocationStatusUnknown, newRevocationValidationError(fmt.Errorf("parsing OCSP response: %w", err))
+	}
+
+	c.cacheResponse(key, parsed)
+
+	return ocspResponseStatus(parsed.Status), nil
+}
+
+func (c *ocspChecker) cachedResponse(key string) (*ocsp.Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resp, ok := c.cache[key]
+	if !ok || time.Now().After(resp.NextUpdate) {
+		return nil, false
+	}
+
+	return resp, true
+}
+
+func (c *ocspChecker) cacheResponse(key string, resp *ocsp.Response) {
+	if resp.NextUpdate.IsZero() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache[key] = resp
+}
+
+func ocspResponseStatus(status int) revocationStatus {
+	switch status {
+	case ocsp.Good:
+		return revocationStatusGood
+	case ocsp.Revoked:
+		return revocationStatusRevoked
+	default:
+		return revocationStatusUnknown
+	}
+}
@@ -0,0 +1,100 @@
+package ca_chain
+
+import (
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"testing"
+
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRevocationChecker struct {
+	status revocationStatus
+	err    error
+}
+
+func (f *fakeRevocationChecker) Check(_, _ *x509.Certificate) (revocationStatus, error) {
+	return f.status, f.err
+}
+
+func certWithSerial(serial int64) *x509.Certificate {
+	return &x509.Certificate{SerialNumber: big.NewInt(serial)}
+}
+
+func TestRevocationResolver_Resolve(t *testing.T) {
+	tests := map[string]struct {
+		mode      RevocationMode
+		checkers  []revocationChecker
+		expectErr bool
+	}{
+		"off skips checking even when a checker would report revoked": {
+			mode:      RevocationModeOff,
+			checkers:  []revocationChecker{&fakeRevocationChecker{status: revocationStatusRevoked}},
+			expectErr: false,
+		},
+		"good status passes": {
+			mode:      RevocationModeSoft,
+			checkers:  []revocationChecker{&fakeRevocationChecker{status: revocationStatusGood}},
+			expectErr: false,
+		},
+		"revoked status hard-fails even in soft mode": {
+			mode:      RevocationModeSoft,
+			checkers:  []revocationChecker{&fakeRevocationChecker{status: revocationStatusRevoked}},
+			expectErr: true,
+		},
+		"checker error is tolerated in soft mode": {
+			mode:      RevocationModeSoft,
+			checkers:  []revocationChecker{&fakeRevocationChecker{err: errors.New("network down")}},
+			expectErr: false,
+		},
+		"checker error hard-fails in strict mode": {
+			mode:      RevocationModeStrict,
+			checkers:  []revocationChecker{&fakeRevocationChecker{err: errors.New("network down")}},
+			expectErr: true,
+		},
+		"validation error hard-fails even in soft mode": {
+			mode: RevocationModeSoft,
+			checkers: []revocationChecker{
+				&fakeRevocationChecker{err: newRevocationValidationError(errors.New("verifying CRL signature: x509: signature algorithm mismatch"))},
+			},
+			expectErr: true,
+		},
+		"unknown status falls through to the next checker": {
+			mode: RevocationModeSoft,
+			checkers: []revocationChecker{
+				&fakeRevocationChecker{status: revocationStatusUnknown},
+				&fakeRevocationChecker{status: revocationStatusRevoked},
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			logger, _ := logrustest.NewNullLogger()
+			r := newRevocationResolver(logger, tt.mode, WithRevocationCheckers(tt.checkers...))
+
+			certs := []*x509.Certificate{certWithSerial(1), certWithSerial(2)}
+			result, err := r.Resolve(certs)
+
+			if tt.expectErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, certs, result)
+		})
+	}
+}
+
+func TestRevokedCertificateError_Error(t *testing.T) {
+	err := &RevokedCertificateError{Serial: big.NewInt(42), Reason: "certificate revoked by issuer"}
+
+	assert.Contains(t, err.Error(), "42")
+	assert.Contains(t, err.Error(), "certificate revoked by issuer")
+}